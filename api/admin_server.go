@@ -0,0 +1,48 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/pikami/cosmium/internal/logger"
+)
+
+// StartAdminServer brings up the optional admin listener on
+// s.config.AdminPort, exposing Prometheus metrics at /metrics and the
+// standard net/http/pprof debug routes. It is kept on a separate port
+// (rather than under the REST router) so profiling/metrics never show up
+// in the emulated Cosmos API namespace. A zero AdminPort disables it.
+func (s *ApiServer) StartAdminServer() error {
+	if s.config.AdminPort == 0 {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	adminServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", s.config.AdminPort),
+		Handler: mux,
+	}
+
+	s.shutdown.BeforeExit(func() {
+		_ = adminServer.Close()
+	})
+
+	go func() {
+		logger.Infof("Listening and serving admin endpoints on %s\n", adminServer.Addr)
+		if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.ErrorLn("Failed to start admin server:", err)
+		}
+	}()
+
+	return nil
+}
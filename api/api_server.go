@@ -0,0 +1,52 @@
+package api
+
+import (
+	"github.com/pikami/cosmium/internal/config"
+	"github.com/pikami/cosmium/internal/shutdown"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApiServer owns one running instance of Cosmium's REST API (and its
+// paired admin server): the Gin router, the listener lifecycle flags,
+// and the shutdown hooks those listeners registered. Each ApiServer gets
+// its own shutdown.Registry, so running more than one instance in the
+// same process (e.g. in tests) never tears down, or leaks hooks onto,
+// another instance.
+type ApiServer struct {
+	config *config.Config
+	router *gin.Engine
+
+	isActive bool
+
+	stopServer       chan bool
+	onServerShutdown chan bool
+
+	shutdown *shutdown.Registry
+}
+
+// NewApiServer creates an ApiServer bound to cfg, ready for CreateRouter
+// and Start.
+func NewApiServer(cfg *config.Config) *ApiServer {
+	return &ApiServer{
+		config:           cfg,
+		stopServer:       make(chan bool),
+		onServerShutdown: make(chan bool),
+		shutdown:         shutdown.NewRegistry(),
+	}
+}
+
+// Stop requests a graceful shutdown of this instance's listeners and
+// blocks until it completes.
+func (s *ApiServer) Stop() {
+	s.stopServer <- true
+	<-s.onServerShutdown
+}
+
+// WaitForSignal blocks until SIGINT/SIGTERM is received, then runs this
+// instance's shutdown hooks (in LIFO order) and returns. It's the
+// long-running entry point for a standalone `cosmium` process, as
+// opposed to Stop, which embedders use to shut down programmatically.
+func (s *ApiServer) WaitForSignal() {
+	s.shutdown.WaitForSignal()
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"github.com/pikami/cosmium/internal/changefeed"
+	"github.com/pikami/cosmium/internal/config"
+	"github.com/pikami/cosmium/internal/datastore"
+	"github.com/pikami/cosmium/internal/scripting"
+)
+
+// Handlers holds the dependencies shared by every route registered in
+// ApiServer.CreateRouter: the in-memory datastore, the server config, the
+// change feed notifier document mutations publish to, and the scripting
+// engine used to execute sprocs. Triggers run inside the datastore itself
+// and share this same engine instance via dataStore.ScriptEngine().
+type Handlers struct {
+	dataStore    datastore.DataStore
+	config       *config.Config
+	changeFeed   *changefeed.Notifier
+	scriptEngine *scripting.Engine
+}
+
+// NewHandlers creates the Handlers shared by every route handler.
+func NewHandlers(dataStore datastore.DataStore, cfg *config.Config) *Handlers {
+	return &Handlers{
+		dataStore:    dataStore,
+		config:       cfg,
+		changeFeed:   dataStore.ChangeFeed(),
+		scriptEngine: dataStore.ScriptEngine(),
+	}
+}
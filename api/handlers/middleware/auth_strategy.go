@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pikami/cosmium/internal/config"
+	"github.com/pikami/cosmium/internal/jwtvalidator"
+	"github.com/pikami/cosmium/internal/rbac"
+)
+
+// NewAuthentication picks the authentication strategy for the router
+// based on cfg.AuthMode. The static Cosmos master-key HMAC check
+// (Authentication) remains the default so existing deployments and SDKs
+// are unaffected; "aad"/"aad-emulator" instead validate an OAuth2 bearer
+// token and map its claims to per-database/collection/operation scopes.
+func NewAuthentication(cfg *config.Config) gin.HandlerFunc {
+	switch cfg.AuthMode {
+	case "aad", "aad-emulator":
+		return bearerTokenAuthentication(cfg)
+	default:
+		return Authentication(cfg)
+	}
+}
+
+// bearerTokenAuthentication validates `Authorization: Bearer <token>`
+// against cfg's configured JWKS URL (or, under aad-emulator, the
+// in-process emulator's static signing key) and checks the token's
+// mapped RBAC scopes against the requested database/collection/operation.
+func bearerTokenAuthentication(cfg *config.Config) gin.HandlerFunc {
+	validator := authValidatorForConfig(cfg)
+
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		tokenString, found := strings.CutPrefix(authHeader, "Bearer ")
+		if !found || tokenString == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"message": "Authorization bearer token is required",
+			})
+			return
+		}
+
+		claims, err := validator.Validate(tokenString)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"message": "Invalid bearer token: " + err.Error(),
+			})
+			return
+		}
+
+		scopes := rbac.ScopesFromClaims(claims)
+		operation := operationForRequest(c)
+		if !rbac.Allowed(scopes, c.Param("databaseId"), c.Param("collId"), operation) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": "Token scopes do not permit this operation",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func authValidatorForConfig(cfg *config.Config) *jwtvalidator.Validator {
+	if cfg.AuthMode == "aad-emulator" {
+		return jwtvalidator.NewStaticValidator(AadEmulatorPublicKey())
+	}
+
+	validator, err := jwtvalidator.NewJWKSValidator(cfg.JwksUrl)
+	if err != nil {
+		// Fail closed: every request is rejected until the JWKS endpoint
+		// is reachable, rather than silently accepting unverifiable
+		// tokens. NewFailingValidator returns the error directly instead
+		// of routing requests through jwt.Parse with no real key, which
+		// would panic deep inside crypto/rsa's verification path.
+		return jwtvalidator.NewFailingValidator(
+			fmt.Errorf("jwtvalidator: JWKS endpoint unavailable: %w", err))
+	}
+	return validator
+}
+
+// operationForRequest maps a request to the Cosmos operation name used in
+// RBAC scope strings (e.g. "docs/create"). The resource kind comes from
+// the matched route, not just the HTTP method: a scope granted over
+// "docs" must not also authorize deleting the collection or database it
+// lives in, so c.FullPath() (which still has its :param placeholders) is
+// what decides whether this is a database, collection, document,
+// trigger, sproc, or UDF operation.
+func operationForRequest(c *gin.Context) string {
+	return resourceKindForRoute(c) + "/" + verbForRequest(c)
+}
+
+// resourceKindForRoute inspects the matched route template (not the
+// resolved path, so ids in the URL don't get mistaken for segment names)
+// to determine which kind of resource this request acts on.
+func resourceKindForRoute(c *gin.Context) string {
+	route := c.FullPath()
+	switch {
+	case strings.Contains(route, "/docs"):
+		return "docs"
+	case strings.Contains(route, "/triggers"):
+		return "triggers"
+	case strings.Contains(route, "/sprocs"):
+		return "sprocs"
+	case strings.Contains(route, "/udfs"):
+		return "udfs"
+	case strings.Contains(route, "/colls"):
+		return "colls"
+	default:
+		return "dbs"
+	}
+}
+
+func verbForRequest(c *gin.Context) string {
+	switch c.Request.Method {
+	case http.MethodPost:
+		if strings.Contains(c.FullPath(), "/sprocs/:sprocId") {
+			return "execute"
+		}
+		return "create"
+	case http.MethodGet:
+		return "read"
+	case http.MethodPut:
+		return "replace"
+	case http.MethodPatch:
+		return "patch"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return "*"
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestOperationForRequest_DistinguishesResourceKindByRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name   string
+		method string
+		route  string
+		path   string
+		want   string
+	}{
+		{"document delete", "DELETE", "/dbs/:databaseId/colls/:collId/docs/:docId", "/dbs/db1/colls/coll1/docs/doc1", "docs/delete"},
+		{"collection delete", "DELETE", "/dbs/:databaseId/colls/:collId", "/dbs/db1/colls/coll1", "colls/delete"},
+		{"database delete", "DELETE", "/dbs/:databaseId", "/dbs/db1", "dbs/delete"},
+		{"sproc create", "POST", "/dbs/:databaseId/colls/:collId/sprocs", "/dbs/db1/colls/coll1/sprocs", "sprocs/create"},
+		{"sproc execute", "POST", "/dbs/:databaseId/colls/:collId/sprocs/:sprocId", "/dbs/db1/colls/coll1/sprocs/sproc1", "sprocs/execute"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(w)
+			engine.Handle(tc.method, tc.route, func(c *gin.Context) {
+				if got := operationForRequest(c); got != tc.want {
+					t.Errorf("operationForRequest() = %q, want %q", got, tc.want)
+				}
+			})
+
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			engine.ServeHTTP(w, req)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pikami/cosmium/internal/metrics"
+)
+
+// Metrics records per-route request counts, latency histograms, and
+// in-flight gauges for the admin /metrics endpoint. It is cheap enough to
+// always run, unlike RequestLogger which is only enabled at the "debug"
+// log level.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		databaseId := c.Param("databaseId")
+		collId := c.Param("collId")
+		operation := routeOperation(c)
+
+		labels := prometheusLabels(databaseId, collId, operation)
+		metrics.RequestsInFlight.WithLabelValues(labels...).Inc()
+		defer metrics.RequestsInFlight.WithLabelValues(labels...).Dec()
+
+		start := time.Now()
+		c.Next()
+
+		metrics.RequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+		metrics.RequestsTotal.WithLabelValues(
+			append(labels, strconv.Itoa(c.Writer.Status()))...,
+		).Inc()
+	}
+}
+
+func prometheusLabels(databaseId, collId, operation string) []string {
+	return []string{databaseId, collId, operation}
+}
+
+// routeOperation maps a Gin route to the short operation label used in
+// metrics (e.g. "doc.post", "doc.query"), mirroring the Cosmos operation
+// names rather than leaking internal handler names.
+func routeOperation(c *gin.Context) string {
+	method := c.Request.Method
+	path := c.FullPath()
+
+	switch {
+	case path == "/dbs/:databaseId/colls/:collId/docs" && method == "POST":
+		return "doc.post"
+	case path == "/dbs/:databaseId/colls/:collId/docs" && method == "GET":
+		return "doc.query"
+	case path == "/dbs/:databaseId/colls/:collId/docs/:docId" && method == "GET":
+		return "doc.get"
+	case path == "/dbs/:databaseId/colls/:collId/docs/:docId" && method == "PUT":
+		return "doc.replace"
+	case path == "/dbs/:databaseId/colls/:collId/docs/:docId" && method == "PATCH":
+		return "doc.patch"
+	case path == "/dbs/:databaseId/colls/:collId/docs/:docId" && method == "DELETE":
+		return "doc.delete"
+	case path == "/dbs/:databaseId/colls/:collId/changes":
+		return "doc.changefeed"
+	default:
+		return method + " " + path
+	}
+}
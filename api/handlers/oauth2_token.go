@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pikami/cosmium/api/handlers/middleware"
+)
+
+// MintOauth2Token serves POST /oauth2/token when the server is started
+// with --auth-mode=aad-emulator. It mints a locally-signed bearer token
+// for the requested `scope` form field, standing in for Azure AD so SDK
+// clients using DefaultAzureCredential against AZURE_TENANT_ID=cosmium
+// succeed without any real AAD tenant.
+func (h *Handlers) MintOauth2Token(c *gin.Context) {
+	scope := c.PostForm("scope")
+
+	token, err := middleware.AadEmulatorMintToken(scope)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to mint token: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token_type":   "Bearer",
+		"access_token": token,
+		"expires_in":   3600,
+	})
+}
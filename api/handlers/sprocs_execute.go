@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pikami/cosmium/internal/datastore"
+	"github.com/pikami/cosmium/internal/scripting"
+)
+
+// ExecuteStoredProcedure serves POST /dbs/:databaseId/colls/:collId/sprocs/:sprocId.
+// The request body, if present, is a JSON array of parameters passed to
+// the sproc as `Arguments`. The sproc runs inside the embedded Lua engine
+// against this collection, with its mutations rolled back automatically
+// if it errors or exceeds its instruction/time budget.
+func (h *Handlers) ExecuteStoredProcedure(c *gin.Context) {
+	databaseId := c.Param("databaseId")
+	collId := c.Param("collId")
+	sprocId := c.Param("sprocId")
+
+	sproc, found := h.dataStore.GetStoredProcedure(databaseId, collId, sprocId)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Stored procedure not found"})
+		return
+	}
+
+	var args []interface{}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&args); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid arguments: " + err.Error()})
+			return
+		}
+	}
+
+	result, err := h.scriptEngine.Run(
+		sproc.Body,
+		datastore.NewCollectionContext(h.dataStore, databaseId, collId),
+		args,
+	)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Stored procedure failed: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, scripting.FromLuaValue(result))
+}
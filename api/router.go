@@ -1,8 +1,9 @@
 package api
 
 import (
-	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
 	"sync"
 	"time"
@@ -12,7 +13,13 @@ import (
 	"github.com/pikami/cosmium/api/handlers/middleware"
 	"github.com/pikami/cosmium/internal/datastore"
 	"github.com/pikami/cosmium/internal/logger"
+	"github.com/pikami/cosmium/internal/shutdown"
 	tlsprovider "github.com/pikami/cosmium/internal/tls_provider"
+	"github.com/soheilhy/cmux"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
 )
 
 var ginMux sync.Mutex
@@ -38,9 +45,19 @@ func (s *ApiServer) CreateRouter(dataStore datastore.DataStore) {
 	}
 
 	router.Use(middleware.StripTrailingSlashes(router, s.config))
-	router.Use(middleware.Authentication(s.config))
+	router.Use(middleware.Metrics())
+
+	// Registered before the authentication middleware below: a client
+	// can't have a bearer token yet when it calls this endpoint to mint
+	// one, so it must not be gated by bearerTokenAuthentication itself.
+	if s.config.AuthMode == "aad-emulator" {
+		router.POST("/oauth2/token", routeHandlers.MintOauth2Token)
+	}
+
+	router.Use(middleware.NewAuthentication(s.config))
 
 	router.GET("/dbs/:databaseId/colls/:collId/pkranges", routeHandlers.GetPartitionKeyRanges)
+	router.GET("/dbs/:databaseId/colls/:collId/changes", routeHandlers.GetChangeFeed)
 
 	router.POST("/dbs/:databaseId/colls/:collId/docs", routeHandlers.DocumentsPost)
 	router.GET("/dbs/:databaseId/colls/:collId/docs", routeHandlers.GetAllDocuments)
@@ -70,6 +87,7 @@ func (s *ApiServer) CreateRouter(dataStore datastore.DataStore) {
 	router.GET("/dbs/:databaseId/colls/:collId/sprocs/:sprocId", routeHandlers.GetStoredProcedure)
 	router.PUT("/dbs/:databaseId/colls/:collId/sprocs/:sprocId", routeHandlers.ReplaceStoredProcedure)
 	router.DELETE("/dbs/:databaseId/colls/:collId/sprocs/:sprocId", routeHandlers.DeleteStoredProcedure)
+	router.POST("/dbs/:databaseId/colls/:collId/sprocs/:sprocId", routeHandlers.ExecuteStoredProcedure)
 
 	router.POST("/dbs/:databaseId/colls/:collId/udfs", routeHandlers.CreateUserDefinedFunction)
 	router.GET("/dbs/:databaseId/colls/:collId/udfs", routeHandlers.GetAllUserDefinedFunctions)
@@ -87,52 +105,129 @@ func (s *ApiServer) CreateRouter(dataStore datastore.DataStore) {
 	s.router = router
 }
 
+// Start brings up a single TCP listener on s.config.Port and uses cmux to
+// dispatch connections on it to the Gin REST API (HTTP/1.1 and h2c) and to
+// a gRPC server reserved for future management RPCs (snapshot/restore,
+// change-feed subscription) — all without opening a second port, whether
+// or not TLS is enabled. When TLS is enabled, ClientHellos are matched
+// first and TLS is terminated with tls.NewListener; the decrypted stream
+// is then cmux'd a second time into the same gRPC/REST split used in the
+// plaintext case.
 func (s *ApiServer) Start() error {
 	listenAddress := fmt.Sprintf(":%d", s.config.Port)
 	s.isActive = true
 
-	server := &http.Server{
-		Addr:    listenAddress,
-		Handler: s.router.Handler(),
+	listener, err := net.Listen("tcp", listenAddress)
+	if err != nil {
+		return err
+	}
+
+	if err := s.StartAdminServer(); err != nil {
+		return err
 	}
 
+	restServer := &http.Server{
+		Handler: s.restHandler(),
+	}
+	grpcServer := grpc.NewServer()
+
+	mux := cmux.New(listener)
+
 	errChan := make(chan error, 1)
+	var listeners errgroup.Group
 
-	go func() {
-		<-s.stopServer
+	var grpcListener, httpListener net.Listener
+
+	if s.config.DisableTls {
+		clearListener := mux.Match(cmux.Any())
+
+		var serveInner func() error
+		grpcListener, httpListener, serveInner = splitGrpcAndHttp(clearListener)
+		listeners.Go(func() error {
+			if err := serveInner(); err != nil && err != cmux.ErrListenerClosed {
+				return err
+			}
+			return nil
+		})
+	} else {
+		rawTlsListener := mux.Match(cmux.TLS())
+
+		tlsConfig := tlsprovider.GetDefaultTlsConfig()
+		if s.config.TLS_CertificatePath != "" && s.config.TLS_CertificateKey != "" {
+			cert, err := tls.LoadX509KeyPair(s.config.TLS_CertificatePath, s.config.TLS_CertificateKey)
+			if err != nil {
+				return err
+			}
+			tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		}
+		tlsListener := tls.NewListener(rawTlsListener, tlsConfig)
+
+		var serveInner func() error
+		grpcListener, httpListener, serveInner = splitGrpcAndHttp(tlsListener)
+		listeners.Go(func() error {
+			logger.Infof("Listening and serving HTTPS on %s\n", listener.Addr())
+			if err := serveInner(); err != nil && err != cmux.ErrListenerClosed {
+				return err
+			}
+			return nil
+		})
+	}
+
+	s.shutdown.BeforeExit(func() {
 		logger.InfoLn("Shutting down server...")
-		err := server.Shutdown(context.TODO())
-		if err != nil {
+		grpcServer.GracefulStop()
+
+		drainCtx, cancel := shutdown.DrainContext(s.config.ShutdownTimeout)
+		defer cancel()
+		if err := restServer.Shutdown(drainCtx); err != nil {
 			logger.ErrorLn("Failed to shutdown server:", err)
 		}
+	})
+
+	go func() {
+		<-s.stopServer
+		s.shutdown.Run()
 		s.onServerShutdown <- true
 	}()
 
-	go func() {
-		var err error
-		if s.config.DisableTls {
-			logger.Infof("Listening and serving HTTP on %s\n", server.Addr)
-			err = server.ListenAndServe()
-		} else if s.config.TLS_CertificatePath != "" && s.config.TLS_CertificateKey != "" {
-			logger.Infof("Listening and serving HTTPS on %s\n", server.Addr)
-			err = server.ListenAndServeTLS(
-				s.config.TLS_CertificatePath,
-				s.config.TLS_CertificateKey)
-		} else {
-			tlsConfig := tlsprovider.GetDefaultTlsConfig()
-			server.TLSConfig = tlsConfig
-
-			logger.Infof("Listening and serving HTTPS on %s\n", server.Addr)
-			err = server.ListenAndServeTLS("", "")
+	listeners.Go(func() error {
+		logger.InfoLn("Serving gRPC admin surface")
+		if err := grpcServer.Serve(grpcListener); err != nil && err != cmux.ErrListenerClosed {
+			return err
 		}
+		return nil
+	})
 
-		if err != nil && err != http.ErrServerClosed {
-			logger.ErrorLn("Failed to start server:", err)
-			errChan <- err
-		} else {
-			errChan <- nil
+	listeners.Go(func() error {
+		// httpListener already yields cleartext HTTP/1.1 and h2c
+		// connections: TLS, when enabled, was terminated by the
+		// tls.Listener wrapped around the matched ClientHellos above, so
+		// Serve (not ServeTLS) is correct in both configurations.
+		logger.Infof("Listening and serving HTTP on %s\n", listener.Addr())
+		if err := restServer.Serve(httpListener); err != nil &&
+			err != http.ErrServerClosed && err != cmux.ErrListenerClosed {
+			return err
 		}
+		return nil
+	})
+
+	listeners.Go(func() error {
+		if err := mux.Serve(); err != nil && err != cmux.ErrListenerClosed {
+			logger.ErrorLn("Failed to multiplex listener:", err)
+			return err
+		}
+		return nil
+	})
+
+	go func() {
+		// Wait returns the first error from any of the listener goroutines
+		// above (REST, TLS, gRPC, or the cmux dispatcher itself) once all
+		// of them have exited. Start returns that error only if this
+		// happens within its 50ms startup window below; after that the
+		// error is not reported, and only isActive is cleared.
+		err := listeners.Wait()
 		s.isActive = false
+		errChan <- err
 	}()
 
 	select {
@@ -142,3 +237,23 @@ func (s *ApiServer) Start() error {
 		return nil
 	}
 }
+
+// splitGrpcAndHttp cmux's a cleartext connection stream (either the raw
+// listener when TLS is disabled, or one already TLS-terminated by Start)
+// into the gRPC admin surface and the Gin REST API, so both can share
+// whichever transport is in play. Pulled out of Start so the dispatch
+// decision itself can be exercised without a full ApiServer.
+func splitGrpcAndHttp(clearListener net.Listener) (grpcListener, httpListener net.Listener, serve func() error) {
+	inner := cmux.New(clearListener)
+	grpcListener = inner.MatchWithWriters(
+		cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc"))
+	httpListener = inner.Match(cmux.Any())
+	return grpcListener, httpListener, inner.Serve
+}
+
+// restHandler wraps the Gin router with an h2c handler so HTTP/2 cleartext
+// requests (as used by some gRPC-Web and Cosmos SDK transports) are served
+// correctly alongside plain HTTP/1.1 on the same cmux'd listener.
+func (s *ApiServer) restHandler() http.Handler {
+	return h2c.NewHandler(s.router.Handler(), &http2.Server{})
+}
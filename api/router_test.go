@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// TestSplitGrpcAndHttp_RoutesByProtocol proves the cmux split really
+// dispatches by protocol rather than, say, always falling through to one
+// side: a plain HTTP/1.1 client lands on httpListener, and a gRPC client
+// (which announces "content-type: application/grpc" on its HTTP/2
+// connection preface) lands on grpcListener instead.
+func TestSplitGrpcAndHttp_RoutesByProtocol(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	grpcListener, httpListener, serve := splitGrpcAndHttp(listener)
+
+	go func() { _ = serve() }()
+
+	grpcServer := grpc.NewServer()
+	go func() { _ = grpcServer.Serve(grpcListener) }()
+	defer grpcServer.Stop()
+
+	httpServer := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	}
+	go func() { _ = httpServer.Serve(httpListener) }()
+	defer httpServer.Close()
+
+	resp, err := http.Get("http://" + listener.Addr().String())
+	if err != nil {
+		t.Fatalf("plain HTTP request failed: %v", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected the HTTP client to reach httpServer, got status %d", resp.StatusCode)
+	}
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to create grpc client: %v", err)
+	}
+	defer conn.Close()
+
+	// Calling a service grpcServer does not register (health) still forces
+	// the client to open its HTTP/2 connection and send the
+	// "content-type: application/grpc" preface cmux matches on; grpcServer
+	// answering Unimplemented proves the connection was routed to
+	// grpcListener, not httpServer.
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if status.Code(err) != codes.Unimplemented {
+		t.Fatalf("expected an Unimplemented reply from grpcServer for an unregistered service, got %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+// Command cosmium runs a standalone Cosmium emulator process: it parses
+// CLI flags into a config.Config, wires up an in-memory DataStore and an
+// ApiServer from it, and serves until SIGINT/SIGTERM.
+package main
+
+import (
+	"os"
+
+	"github.com/pikami/cosmium/api"
+	"github.com/pikami/cosmium/internal/config"
+	"github.com/pikami/cosmium/internal/datastore"
+)
+
+func main() {
+	cfg := config.ParseFlags(os.Args[1:])
+
+	dataStore := datastore.NewInMemoryDataStore(cfg.ChangeFeedReplayFromBeginning)
+
+	server := api.NewApiServer(cfg)
+	server.CreateRouter(dataStore)
+	if err := server.Start(); err != nil {
+		panic(err)
+	}
+
+	server.WaitForSignal()
+}
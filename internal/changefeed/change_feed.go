@@ -0,0 +1,178 @@
+// Package changefeed implements a minimal emulation of the Cosmos DB
+// change feed: a monotonically increasing per-collection sequence number
+// (`_lsn`) attached to every document mutation, and a fan-out mechanism
+// that lets HTTP handlers subscribe to new entries for a given
+// database/collection pair without polling the datastore.
+package changefeed
+
+import "sync"
+
+// Operation identifies the kind of mutation that produced a change feed Entry.
+type Operation string
+
+const (
+	OperationCreate  Operation = "create"
+	OperationReplace Operation = "replace"
+	OperationPatch   Operation = "patch"
+	OperationDelete  Operation = "delete"
+)
+
+// Entry is a single change feed record for one document mutation. Real
+// Cosmos DB orders the feed per logical partition; Cosmium's collections
+// have no partition key concept to scope by, so Entry carries none and
+// the feed is a single flat order per collection instead.
+type Entry struct {
+	LSN       int64
+	Operation Operation
+	Document  map[string]interface{}
+}
+
+// collectionKey identifies a collection within the Notifier's bookkeeping.
+type collectionKey struct {
+	DatabaseId   string
+	CollectionId string
+}
+
+// subscriber receives every Entry published for its collection after it subscribed.
+type subscriber struct {
+	ch chan Entry
+}
+
+// Notifier tracks the next `_lsn` to assign per collection and fans out
+// newly published entries to any active subscribers (used by the SSE /
+// long-poll branch of the changes endpoint). Polling clients instead read
+// the buffered Entries directly via Since.
+type Notifier struct {
+	mu          sync.Mutex
+	nextLSN     map[collectionKey]int64
+	entries     map[collectionKey][]Entry
+	subscribers map[collectionKey][]*subscriber
+
+	// replayFromBeginning controls StartLSN's behavior: see NewNotifier.
+	replayFromBeginning bool
+}
+
+// NewNotifier creates an empty Notifier. Real Cosmos change feed readers
+// that start without a continuation token only see changes from that
+// point forward; when replayFromBeginning is true (the `cosmium`-scoped
+// `--change-feed-replay-from-beginning` testing option), a fresh
+// subscription with no continuation token instead sees every entry
+// recorded so far, which is convenient for deterministic test scenarios.
+func NewNotifier(replayFromBeginning bool) *Notifier {
+	return &Notifier{
+		nextLSN:             make(map[collectionKey]int64),
+		entries:             make(map[collectionKey][]Entry),
+		subscribers:         make(map[collectionKey][]*subscriber),
+		replayFromBeginning: replayFromBeginning,
+	}
+}
+
+// StartLSN returns the LSN a fresh subscription (no continuation token)
+// should treat as "already seen", per NewNotifier's replayFromBeginning
+// setting: 0 to replay every entry, or the collection's current LSN to
+// only see entries published from now on.
+func (n *Notifier) StartLSN(databaseId, collectionId string) int64 {
+	if n.replayFromBeginning {
+		return 0
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.nextLSN[collectionKey{databaseId, collectionId}]
+}
+
+// NextLSN assigns and returns the next `_lsn` for the given collection.
+// Datastore mutation paths (insert/replace/patch/delete) call this once
+// per mutation; insert/replace/patch stamp the result onto the document
+// before persisting it, while delete only uses it as the LSN of its change
+// feed entry.
+func (n *Notifier) NextLSN(databaseId, collectionId string) int64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	key := collectionKey{databaseId, collectionId}
+	n.nextLSN[key]++
+	return n.nextLSN[key]
+}
+
+// Publish records the entry and pushes it to any subscribers currently
+// waiting on this collection's change feed.
+func (n *Notifier) Publish(databaseId, collectionId string, entry Entry) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	key := collectionKey{databaseId, collectionId}
+	n.entries[key] = append(n.entries[key], entry)
+
+	for _, sub := range n.subscribers[key] {
+		select {
+		case sub.ch <- entry:
+		default:
+			// Slow subscriber; drop rather than block the write path.
+		}
+	}
+}
+
+// Retract removes the entry with the given LSN, used to undo a
+// mutation's change feed publish when a sproc/UDF/trigger transaction
+// that produced it is rolled back. Already-connected Subscribe
+// listeners may still have seen the entry go by; Retract only keeps it
+// from showing up to Since callers (polling or future subscriptions)
+// from this point on.
+func (n *Notifier) Retract(databaseId, collectionId string, lsn int64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	key := collectionKey{databaseId, collectionId}
+	entries := n.entries[key]
+	for i, e := range entries {
+		if e.LSN == lsn {
+			n.entries[key] = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+}
+
+// Since returns every entry for the collection with LSN strictly greater
+// than afterLSN, in insertion order. Used by the polling (A-IM) branch.
+func (n *Notifier) Since(databaseId, collectionId string, afterLSN int64) []Entry {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	key := collectionKey{databaseId, collectionId}
+	var result []Entry
+	for _, e := range n.entries[key] {
+		if e.LSN > afterLSN {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// Subscribe registers a new listener for live updates on a collection and
+// returns a channel of future entries plus a cancel func that must be
+// called to unregister it. Used by the SSE / long-poll streaming branch.
+func (n *Notifier) Subscribe(databaseId, collectionId string) (<-chan Entry, func()) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	key := collectionKey{databaseId, collectionId}
+	sub := &subscriber{ch: make(chan Entry, 16)}
+	n.subscribers[key] = append(n.subscribers[key], sub)
+
+	cancel := func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+
+		subs := n.subscribers[key]
+		for i, s := range subs {
+			if s == sub {
+				n.subscribers[key] = append(subs[:i], subs[i+1:]...)
+				close(sub.ch)
+				break
+			}
+		}
+	}
+
+	return sub.ch, cancel
+}
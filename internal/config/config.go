@@ -0,0 +1,79 @@
+// Package config defines Cosmium's runtime configuration and how it is
+// populated from CLI flags, so every tunable added by a feature (TLS,
+// the admin listener, auth mode, change feed replay, ...) is actually
+// reachable by an operator instead of only existing as a struct field
+// some other package happens to read.
+package config
+
+import (
+	"flag"
+	"os"
+	"time"
+)
+
+// Config is the configuration shared by ApiServer and everything it
+// wires up (the REST router, the admin server, the auth middleware).
+type Config struct {
+	// Port is the TCP port ApiServer.Start listens on.
+	Port int
+	// AdminPort is the TCP port StartAdminServer exposes /metrics and
+	// pprof on. Zero disables the admin server entirely.
+	AdminPort int
+
+	// LogLevel is "debug" or "info"; "debug" additionally enables Gin's
+	// request logger.
+	LogLevel string
+
+	// DisableTls serves plaintext HTTP/h2c instead of terminating TLS in
+	// ApiServer.Start.
+	DisableTls bool
+	// TLS_CertificatePath and TLS_CertificateKey, if both set, are loaded
+	// as the server's certificate instead of tlsprovider's self-signed
+	// default.
+	TLS_CertificatePath string
+	TLS_CertificateKey  string
+
+	// ShutdownTimeout bounds how long a graceful shutdown waits for
+	// in-flight requests to drain before the process moves on.
+	ShutdownTimeout time.Duration
+
+	// AuthMode selects the authentication strategy NewAuthentication
+	// builds: "key" (the default Cosmos master-key HMAC check), "aad"
+	// (JWKS-backed bearer tokens), or "aad-emulator" (a static in-process
+	// signing key, for local/offline testing).
+	AuthMode string
+	// JwksUrl is the JWKS endpoint bearer tokens are verified against
+	// when AuthMode is "aad".
+	JwksUrl string
+
+	// ChangeFeedReplayFromBeginning is the `cosmium`-scoped testing
+	// option that makes a fresh change feed subscription with no
+	// continuation token replay every past entry instead of only those
+	// published from that point on. See changefeed.NewNotifier.
+	ChangeFeedReplayFromBeginning bool
+}
+
+// ParseFlags populates a Config from CLI flags (parsing args, which is
+// os.Args[1:] for a nil args), applying the same defaults Cosmium has
+// always started with when a flag is omitted.
+func ParseFlags(args []string) *Config {
+	fs := flag.NewFlagSet("cosmium", flag.ExitOnError)
+
+	cfg := &Config{}
+	fs.IntVar(&cfg.Port, "port", 8081, "Port to listen on")
+	fs.IntVar(&cfg.AdminPort, "admin-port", 0, "Port to serve /metrics and pprof on (0 disables the admin server)")
+	fs.StringVar(&cfg.LogLevel, "log-level", "info", "Log level (\"debug\" or \"info\")")
+	fs.BoolVar(&cfg.DisableTls, "disable-tls", false, "Serve plaintext HTTP instead of terminating TLS")
+	fs.StringVar(&cfg.TLS_CertificatePath, "tls-certificate-path", "", "Path to a TLS certificate; falls back to a self-signed default")
+	fs.StringVar(&cfg.TLS_CertificateKey, "tls-certificate-key", "", "Path to the TLS certificate's private key")
+	fs.DurationVar(&cfg.ShutdownTimeout, "shutdown-timeout", 5*time.Second, "How long to wait for in-flight requests to drain on shutdown")
+	fs.StringVar(&cfg.AuthMode, "auth-mode", "key", "Authentication strategy: \"key\", \"aad\", or \"aad-emulator\"")
+	fs.StringVar(&cfg.JwksUrl, "jwks-url", "", "JWKS endpoint used to verify bearer tokens when auth-mode is \"aad\"")
+	fs.BoolVar(&cfg.ChangeFeedReplayFromBeginning, "change-feed-replay-from-beginning", false, "Make fresh change feed subscriptions replay every past entry instead of only new ones")
+
+	if args == nil {
+		args = os.Args[1:]
+	}
+	_ = fs.Parse(args)
+	return cfg
+}
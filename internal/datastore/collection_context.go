@@ -0,0 +1,55 @@
+package datastore
+
+import (
+	"fmt"
+
+	"github.com/pikami/cosmium/internal/scripting"
+)
+
+// collectionContext adapts a DataStore to scripting.CollectionContext,
+// scoping every call to a single database/collection pair so a sproc or
+// UDF script cannot reach across collections. Shared by sproc execution
+// (handlers) and UDF evaluation (the query parser).
+type collectionContext struct {
+	dataStore    DataStore
+	databaseId   string
+	collectionId string
+}
+
+// NewCollectionContext builds the scripting.CollectionContext a sproc or
+// UDF script runs against for one database/collection pair.
+func NewCollectionContext(dataStore DataStore, databaseId, collectionId string) scripting.CollectionContext {
+	return &collectionContext{
+		dataStore:    dataStore,
+		databaseId:   databaseId,
+		collectionId: collectionId,
+	}
+}
+
+func (c *collectionContext) ReadDocument(id string) (map[string]interface{}, error) {
+	doc, found := c.dataStore.GetDocument(c.databaseId, c.collectionId, id)
+	if !found {
+		return nil, fmt.Errorf("document %q not found", id)
+	}
+	return doc, nil
+}
+
+func (c *collectionContext) CreateDocument(doc map[string]interface{}) (map[string]interface{}, error) {
+	return c.dataStore.CreateDocument(c.databaseId, c.collectionId, doc)
+}
+
+func (c *collectionContext) ReplaceDocument(id string, doc map[string]interface{}) (map[string]interface{}, error) {
+	return c.dataStore.ReplaceDocument(c.databaseId, c.collectionId, id, doc)
+}
+
+func (c *collectionContext) DeleteDocument(id string) (int64, error) {
+	return c.dataStore.DeleteDocument(c.databaseId, c.collectionId, id)
+}
+
+func (c *collectionContext) QueryDocuments(query string, parameters map[string]interface{}) ([]map[string]interface{}, error) {
+	return c.dataStore.QueryDocuments(c.databaseId, c.collectionId, query, parameters)
+}
+
+func (c *collectionContext) RestoreDocument(id string, doc map[string]interface{}, existed bool, lsn int64) error {
+	return c.dataStore.RestoreDocument(c.databaseId, c.collectionId, id, doc, existed, lsn)
+}
@@ -0,0 +1,49 @@
+// Package datastore is Cosmium's in-memory document store. It is the
+// single choke point every document mutation passes through, which is
+// why both the change feed's `_lsn`/notification bookkeeping and
+// pre/post trigger execution are wired in here rather than in the HTTP
+// handlers that call it.
+package datastore
+
+import (
+	"github.com/pikami/cosmium/internal/changefeed"
+	"github.com/pikami/cosmium/internal/scripting"
+)
+
+// DataStore is the interface route handlers and the scripting engine's
+// collection context use to read and mutate documents, independent of
+// the underlying storage implementation.
+type DataStore interface {
+	GetDocument(databaseId, collId, docId string) (Document, bool)
+	CreateDocument(databaseId, collId string, doc Document) (Document, error)
+	ReplaceDocument(databaseId, collId, docId string, doc Document) (Document, error)
+	PatchDocument(databaseId, collId, docId string, patch Document) (Document, error)
+	// DeleteDocument returns the `_lsn` assigned to the delete's change
+	// feed entry, so a transaction rollback can retract that entry via
+	// RestoreDocument if the delete is later undone.
+	DeleteDocument(databaseId, collId, docId string) (int64, error)
+	QueryDocuments(databaseId, collId, query string, parameters map[string]interface{}) ([]Document, error)
+
+	// RestoreDocument puts docId's document back to the exact state a
+	// trigger/sproc/UDF transaction rollback needs: doc as-is if existed
+	// is true, or removed entirely if existed is false. lsn is the `_lsn`
+	// the mutation being undone published, which RestoreDocument retracts
+	// from the change feed; it fires no triggers and assigns no new
+	// `_lsn` of its own — see scripting.CollectionContext.RestoreDocument.
+	RestoreDocument(databaseId, collId, docId string, doc Document, existed bool, lsn int64) error
+
+	GetStoredProcedure(databaseId, collId, sprocId string) (StoredProcedure, bool)
+	GetTrigger(databaseId, collId, triggerId string) (Trigger, bool)
+	GetUserDefinedFunction(databaseId, collId, udfId string) (UserDefinedFunction, bool)
+
+	// ChangeFeed returns the notifier that Since/Subscribe (used by the
+	// /changes endpoint) read from; CreateDocument/ReplaceDocument/
+	// PatchDocument/DeleteDocument publish to it on every mutation.
+	ChangeFeed() *changefeed.Notifier
+
+	// ScriptEngine returns the engine this store uses to run its own
+	// pre/post triggers, so handlers (sproc execution, UDF calls from the
+	// query parser) share the same engine instance rather than each
+	// spinning up their own.
+	ScriptEngine() *scripting.Engine
+}
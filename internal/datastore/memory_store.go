@@ -0,0 +1,337 @@
+package datastore
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/pikami/cosmium/internal/changefeed"
+	"github.com/pikami/cosmium/internal/metrics"
+	"github.com/pikami/cosmium/internal/scripting"
+)
+
+type collectionKey struct {
+	DatabaseId   string
+	CollectionId string
+}
+
+// InMemoryDataStore is Cosmium's default DataStore: everything lives in
+// process memory, guarded by a single mutex. Every document mutation
+// assigns the next `_lsn` for its collection and publishes the result to
+// the change feed notifier before returning.
+type InMemoryDataStore struct {
+	mu sync.Mutex
+
+	documents map[collectionKey]map[string]Document
+	triggers  map[collectionKey]map[string]Trigger
+	sprocs    map[collectionKey]map[string]StoredProcedure
+	udfs      map[collectionKey]map[string]UserDefinedFunction
+
+	changeFeed   *changefeed.Notifier
+	scriptEngine *scripting.Engine
+}
+
+// NewInMemoryDataStore creates an empty store. replayFromBeginning is the
+// `cosmium`-scoped testing option that makes a fresh change feed
+// subscription (no continuation token) replay every past entry instead of
+// only those published from that point on; see changefeed.NewNotifier.
+func NewInMemoryDataStore(replayFromBeginning bool) *InMemoryDataStore {
+	return &InMemoryDataStore{
+		documents:    make(map[collectionKey]map[string]Document),
+		triggers:     make(map[collectionKey]map[string]Trigger),
+		sprocs:       make(map[collectionKey]map[string]StoredProcedure),
+		udfs:         make(map[collectionKey]map[string]UserDefinedFunction),
+		changeFeed:   changefeed.NewNotifier(replayFromBeginning),
+		scriptEngine: scripting.NewEngine(scripting.Budget{}),
+	}
+}
+
+func (s *InMemoryDataStore) ChangeFeed() *changefeed.Notifier {
+	return s.changeFeed
+}
+
+func (s *InMemoryDataStore) ScriptEngine() *scripting.Engine {
+	return s.scriptEngine
+}
+
+// runTriggers executes every registered trigger matching op/kind against
+// document, in turn, returning the fully-transformed document. It must be
+// called without s.mu held, since triggersFor takes s.mu to look up the
+// matching triggers; the trigger scripts themselves only touch the
+// in-flight document via getRequest().getBody()/setBody(), never the
+// datastore.
+func (s *InMemoryDataStore) runTriggers(databaseId, collId string, op TriggerOperation, kind TriggerType, document Document) (Document, error) {
+	for _, trigger := range s.triggersFor(databaseId, collId, op, kind) {
+		result, err := s.scriptEngine.RunTrigger(trigger.Body, document)
+		if err != nil {
+			return nil, fmt.Errorf("trigger %q failed: %w", trigger.Id, err)
+		}
+		document = result
+	}
+	return document, nil
+}
+
+func (s *InMemoryDataStore) GetDocument(databaseId, collId, docId string) (Document, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, found := s.documents[collectionKey{databaseId, collId}][docId]
+	return doc, found
+}
+
+func (s *InMemoryDataStore) CreateDocument(databaseId, collId string, doc Document) (Document, error) {
+	id, _ := doc["id"].(string)
+	if id == "" {
+		return nil, fmt.Errorf("document is missing an id")
+	}
+
+	doc, err := s.runTriggers(databaseId, collId, TriggerOperationCreate, TriggerTypePre, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	key := collectionKey{databaseId, collId}
+	if s.documents[key] == nil {
+		s.documents[key] = make(map[string]Document)
+	}
+	if _, exists := s.documents[key][id]; exists {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("document %q already exists", id)
+	}
+
+	lsn := s.changeFeed.NextLSN(databaseId, collId)
+	doc["id"] = id
+	doc["_lsn"] = lsn
+	s.documents[key][id] = doc
+	s.mu.Unlock()
+
+	s.changeFeed.Publish(databaseId, collId, changefeed.Entry{
+		LSN:       lsn,
+		Operation: changefeed.OperationCreate,
+		Document:  doc,
+	})
+	s.updateDatastoreMetrics(databaseId, collId)
+
+	if _, err := s.runTriggers(databaseId, collId, TriggerOperationCreate, TriggerTypePost, doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func (s *InMemoryDataStore) ReplaceDocument(databaseId, collId, docId string, doc Document) (Document, error) {
+	s.mu.Lock()
+	key := collectionKey{databaseId, collId}
+	if _, exists := s.documents[key][docId]; !exists {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("document %q not found", docId)
+	}
+	s.mu.Unlock()
+
+	doc, err := s.runTriggers(databaseId, collId, TriggerOperationReplace, TriggerTypePre, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	lsn := s.changeFeed.NextLSN(databaseId, collId)
+	doc["id"] = docId
+	doc["_lsn"] = lsn
+	s.documents[key][docId] = doc
+	s.mu.Unlock()
+
+	s.changeFeed.Publish(databaseId, collId, changefeed.Entry{
+		LSN:       lsn,
+		Operation: changefeed.OperationReplace,
+		Document:  doc,
+	})
+	s.updateDatastoreMetrics(databaseId, collId)
+
+	if _, err := s.runTriggers(databaseId, collId, TriggerOperationReplace, TriggerTypePost, doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func (s *InMemoryDataStore) PatchDocument(databaseId, collId, docId string, patch Document) (Document, error) {
+	s.mu.Lock()
+	key := collectionKey{databaseId, collId}
+	existing, found := s.documents[key][docId]
+	if !found {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("document %q not found", docId)
+	}
+
+	doc := make(Document, len(existing)+len(patch))
+	for field, value := range existing {
+		doc[field] = value
+	}
+	for field, value := range patch {
+		doc[field] = value
+	}
+	s.mu.Unlock()
+
+	doc, err := s.runTriggers(databaseId, collId, TriggerOperationPatch, TriggerTypePre, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	lsn := s.changeFeed.NextLSN(databaseId, collId)
+	doc["id"] = docId
+	doc["_lsn"] = lsn
+	s.documents[key][docId] = doc
+	s.mu.Unlock()
+
+	s.changeFeed.Publish(databaseId, collId, changefeed.Entry{
+		LSN:       lsn,
+		Operation: changefeed.OperationPatch,
+		Document:  doc,
+	})
+	s.updateDatastoreMetrics(databaseId, collId)
+
+	if _, err := s.runTriggers(databaseId, collId, TriggerOperationPatch, TriggerTypePost, doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// DeleteDocument removes docId and returns the `_lsn` assigned to the
+// delete's change feed entry, so a sproc/UDF/trigger transaction that
+// later rolls back this delete can retract that exact entry via
+// RestoreDocument.
+func (s *InMemoryDataStore) DeleteDocument(databaseId, collId, docId string) (int64, error) {
+	s.mu.Lock()
+	key := collectionKey{databaseId, collId}
+	doc, found := s.documents[key][docId]
+	s.mu.Unlock()
+	if !found {
+		return 0, fmt.Errorf("document %q not found", docId)
+	}
+
+	if _, err := s.runTriggers(databaseId, collId, TriggerOperationDelete, TriggerTypePre, doc); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	delete(s.documents[key], docId)
+	lsn := s.changeFeed.NextLSN(databaseId, collId)
+	s.mu.Unlock()
+
+	s.changeFeed.Publish(databaseId, collId, changefeed.Entry{
+		LSN:       lsn,
+		Operation: changefeed.OperationDelete,
+		Document:  doc,
+	})
+	s.updateDatastoreMetrics(databaseId, collId)
+
+	if _, err := s.runTriggers(databaseId, collId, TriggerOperationDelete, TriggerTypePost, doc); err != nil {
+		return lsn, err
+	}
+
+	return lsn, nil
+}
+
+// RestoreDocument puts a document back exactly as it was before a
+// trigger/sproc/UDF transaction's mutation: no triggers fire, and no new
+// `_lsn` is assigned. lsn is the `_lsn` the mutation being undone
+// published to the change feed (returned to the caller by
+// CreateDocument/ReplaceDocument/PatchDocument/DeleteDocument at the
+// time); RestoreDocument retracts that exact change feed entry and
+// refreshes the document count/bytes gauges, so a rolled-back script
+// leaves no trace beyond undoing its own writes.
+func (s *InMemoryDataStore) RestoreDocument(databaseId, collId, docId string, doc Document, existed bool, lsn int64) error {
+	s.mu.Lock()
+	key := collectionKey{databaseId, collId}
+	if !existed {
+		delete(s.documents[key], docId)
+	} else {
+		if s.documents[key] == nil {
+			s.documents[key] = make(map[string]Document)
+		}
+		s.documents[key][docId] = doc
+	}
+	s.mu.Unlock()
+
+	s.changeFeed.Retract(databaseId, collId, lsn)
+	s.updateDatastoreMetrics(databaseId, collId)
+	return nil
+}
+
+// QueryDocuments is a minimal stand-in used by sprocs/UDFs: it ignores
+// query and parameters and returns every document in the collection, in
+// no particular order. The
+// real SQL-like query grammar lives in the (not shown here) query parser
+// package and is out of scope for this store.
+func (s *InMemoryDataStore) QueryDocuments(databaseId, collId, query string, parameters map[string]interface{}) ([]Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := collectionKey{databaseId, collId}
+	result := make([]Document, 0, len(s.documents[key]))
+	for _, doc := range s.documents[key] {
+		result = append(result, doc)
+	}
+	return result, nil
+}
+
+func (s *InMemoryDataStore) GetStoredProcedure(databaseId, collId, sprocId string) (StoredProcedure, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sproc, found := s.sprocs[collectionKey{databaseId, collId}][sprocId]
+	return sproc, found
+}
+
+func (s *InMemoryDataStore) GetTrigger(databaseId, collId, triggerId string) (Trigger, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trigger, found := s.triggers[collectionKey{databaseId, collId}][triggerId]
+	return trigger, found
+}
+
+func (s *InMemoryDataStore) GetUserDefinedFunction(databaseId, collId, udfId string) (UserDefinedFunction, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	udf, found := s.udfs[collectionKey{databaseId, collId}][udfId]
+	return udf, found
+}
+
+// updateDatastoreMetrics refreshes the cosmium_datastore_documents and
+// cosmium_datastore_bytes gauges for one collection. It is called after
+// every document mutation, outside of s.mu, so it takes its own
+// snapshot of the collection rather than assuming the caller still holds
+// the lock.
+func (s *InMemoryDataStore) updateDatastoreMetrics(databaseId, collId string) {
+	s.mu.Lock()
+	docs := s.documents[collectionKey{databaseId, collId}]
+	count := len(docs)
+	size, err := json.Marshal(docs)
+	s.mu.Unlock()
+
+	metrics.DocumentCount.WithLabelValues(databaseId, collId).Set(float64(count))
+	if err == nil {
+		metrics.DatastoreBytes.WithLabelValues(databaseId, collId).Set(float64(len(size)))
+	}
+}
+
+// triggersFor returns every trigger registered for the given collection
+// whose TriggerOperation/TriggerType match, in no particular order; used
+// by runTriggers to run pre/post triggers around a mutation.
+func (s *InMemoryDataStore) triggersFor(databaseId, collId string, op TriggerOperation, kind TriggerType) []Trigger {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matches []Trigger
+	for _, trigger := range s.triggers[collectionKey{databaseId, collId}] {
+		if trigger.TriggerOperation == op && trigger.TriggerType == kind {
+			matches = append(matches, trigger)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,52 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/pikami/cosmium/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInMemoryDataStore_UpdatesDocumentCountGaugeOnEveryMutation(t *testing.T) {
+	store := NewInMemoryDataStore(false)
+	databaseId, collId := "db1", "coll1"
+	gauge := metrics.DocumentCount.WithLabelValues(databaseId, collId)
+
+	if _, err := store.CreateDocument(databaseId, collId, Document{"id": "doc1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := testutil.ToFloat64(gauge); got != 1 {
+		t.Fatalf("expected 1 document after create, got %v", got)
+	}
+
+	if _, err := store.CreateDocument(databaseId, collId, Document{"id": "doc2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := testutil.ToFloat64(gauge); got != 2 {
+		t.Fatalf("expected 2 documents after a second create, got %v", got)
+	}
+
+	if _, err := store.DeleteDocument(databaseId, collId, "doc1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := testutil.ToFloat64(gauge); got != 1 {
+		t.Fatalf("expected 1 document after delete, got %v", got)
+	}
+}
+
+func TestInMemoryDataStore_UpdatesDatastoreBytesGaugeOnEveryMutation(t *testing.T) {
+	store := NewInMemoryDataStore(false)
+	databaseId, collId := "db1", "coll2"
+	gauge := metrics.DatastoreBytes.WithLabelValues(databaseId, collId)
+
+	if got := testutil.ToFloat64(gauge); got != 0 {
+		t.Fatalf("expected an empty collection to report 0 bytes, got %v", got)
+	}
+
+	if _, err := store.CreateDocument(databaseId, collId, Document{"id": "doc1", "value": "hello"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := testutil.ToFloat64(gauge); got <= 0 {
+		t.Fatalf("expected a positive byte count after creating a document, got %v", got)
+	}
+}
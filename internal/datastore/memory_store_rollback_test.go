@@ -0,0 +1,43 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/pikami/cosmium/internal/metrics"
+	"github.com/pikami/cosmium/internal/scripting"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestInMemoryDataStore_RollbackRetractsChangeFeedAndMetrics runs
+// TestEngine_Run_RollsBackMutationsOnError's scenario (a sproc that
+// mutates then errors) through the real InMemoryDataStore instead of a
+// fake CollectionContext, to prove rollback undoes every externally
+// visible side effect of the mutation it undoes, not just the document
+// map entry.
+func TestInMemoryDataStore_RollbackRetractsChangeFeedAndMetrics(t *testing.T) {
+	store := NewInMemoryDataStore(false)
+	databaseId, collId := "db1", "coll1"
+	docGauge := metrics.DocumentCount.WithLabelValues(databaseId, collId)
+	engine := scripting.NewEngine(scripting.Budget{})
+	ctx := NewCollectionContext(store, databaseId, collId)
+
+	startLSN := store.ChangeFeed().StartLSN(databaseId, collId)
+
+	_, err := engine.Run(`
+		getContext().getCollection().createDocument({ id = "doc1" })
+		error("boom")
+	`, ctx, nil)
+	if err == nil {
+		t.Fatal("expected the script error to be returned")
+	}
+
+	if _, found := store.GetDocument(databaseId, collId, "doc1"); found {
+		t.Fatal("expected createDocument's mutation to be rolled back")
+	}
+	if got := testutil.ToFloat64(docGauge); got != 0 {
+		t.Fatalf("expected the document count gauge to roll back to 0, got %v", got)
+	}
+	if entries := store.ChangeFeed().Since(databaseId, collId, startLSN); len(entries) != 0 {
+		t.Fatalf("expected the rolled-back create's change feed entry to be retracted, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,46 @@
+package datastore
+
+// Document is a single Cosmos document, keyed by its JSON property names.
+type Document = map[string]interface{}
+
+// TriggerOperation identifies which mutation a Trigger is scoped to.
+type TriggerOperation string
+
+const (
+	TriggerOperationCreate  TriggerOperation = "Create"
+	TriggerOperationReplace TriggerOperation = "Replace"
+	TriggerOperationPatch   TriggerOperation = "Patch"
+	TriggerOperationDelete  TriggerOperation = "Delete"
+)
+
+// TriggerType identifies whether a Trigger runs before or after its
+// TriggerOperation is applied.
+type TriggerType string
+
+const (
+	TriggerTypePre  TriggerType = "Pre"
+	TriggerTypePost TriggerType = "Post"
+)
+
+// Trigger is a stored pre/post trigger, executed by the embedded
+// scripting engine around document mutations.
+type Trigger struct {
+	Id               string
+	Body             string
+	TriggerOperation TriggerOperation
+	TriggerType      TriggerType
+}
+
+// StoredProcedure is a stored procedure body, executed on demand via
+// ExecuteStoredProcedure.
+type StoredProcedure struct {
+	Id   string
+	Body string
+}
+
+// UserDefinedFunction is a stored UDF body, executed by the query parser
+// when a query references its name as a function call.
+type UserDefinedFunction struct {
+	Id   string
+	Body string
+}
@@ -0,0 +1,77 @@
+// Package jwtvalidator validates OAuth2 bearer tokens used by Cosmium's
+// AAD/OAuth2 authentication mode, either against a JWKS endpoint (real
+// Azure AD, or any other OIDC provider) or a single configured static
+// public key (for offline/tests).
+package jwtvalidator
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/MicahParks/keyfunc/v3"
+)
+
+// Validator validates bearer tokens and returns their claims.
+type Validator struct {
+	keyFunc jwt.Keyfunc
+	// err, when set, makes every Validate call fail immediately without
+	// touching keyFunc; used for a validator that must fail closed (e.g.
+	// the JWKS endpoint could not be reached) without routing requests
+	// through jwt.Parse with a key that doesn't really exist.
+	err error
+}
+
+// NewJWKSValidator builds a Validator that fetches and caches signing
+// keys from jwksURL, refreshing them as the provider rotates keys.
+func NewJWKSValidator(jwksURL string) (*Validator, error) {
+	jwks, err := keyfunc.NewDefaultCtx(context.Background(), []string{jwksURL})
+	if err != nil {
+		return nil, fmt.Errorf("jwtvalidator: fetching JWKS: %w", err)
+	}
+
+	return &Validator{keyFunc: jwks.Keyfunc}, nil
+}
+
+// NewStaticValidator builds a Validator that checks every token's
+// signature against a single fixed RSA public key, intended for local
+// testing and the `aad-emulator` auth mode.
+func NewStaticValidator(publicKey *rsa.PublicKey) *Validator {
+	return &Validator{
+		keyFunc: func(*jwt.Token) (interface{}, error) {
+			return publicKey, nil
+		},
+	}
+}
+
+// NewFailingValidator builds a Validator whose Validate always returns
+// err without ever invoking jwt.Parse. It exists for fail-closed
+// fallbacks (e.g. the configured JWKS endpoint is unreachable): those
+// callers have no real key to check signatures against, and handing
+// jwt.Parse a nil key panics deep inside crypto/rsa's verification path
+// instead of cleanly rejecting the request.
+func NewFailingValidator(err error) *Validator {
+	return &Validator{err: err}
+}
+
+// Validate parses and verifies tokenString, returning its claims if (and
+// only if) the signature and standard registered claims (exp, nbf, ...)
+// are valid.
+func (v *Validator) Validate(tokenString string) (map[string]interface{}, error) {
+	if v.err != nil {
+		return nil, v.err
+	}
+
+	token, err := jwt.Parse(tokenString, v.keyFunc, jwt.WithValidMethods([]string{"RS256"}))
+	if err != nil {
+		return nil, fmt.Errorf("jwtvalidator: %w", err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("jwtvalidator: token is not valid")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,26 @@
+package jwtvalidator
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestFailingValidator_ReturnsErrorWithoutPanicking exercises the
+// fail-closed fallback authValidatorForConfig uses when the JWKS endpoint
+// is unreachable: Validate must reject the request with a clean error
+// rather than handing a non-existent key to jwt.Parse's real
+// verification path, which panics.
+func TestFailingValidator_ReturnsErrorWithoutPanicking(t *testing.T) {
+	validator := NewFailingValidator(errors.New("JWKS endpoint unavailable"))
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("Validate panicked instead of failing closed: %v", r)
+		}
+	}()
+
+	_, err := validator.Validate("not-a-real-token")
+	if err == nil {
+		t.Fatal("expected Validate to return an error")
+	}
+}
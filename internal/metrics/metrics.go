@@ -0,0 +1,48 @@
+// Package metrics holds the Prometheus collectors exposed on the admin
+// listener's /metrics endpoint, so load-testing Cosmium has a real
+// observability surface without polluting the emulated Cosmos API
+// namespace.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// RequestsTotal counts every REST request, labeled by database,
+	// collection, response status, and the Cosmos operation it maps to
+	// (e.g. "doc.post") rather than the internal handler name.
+	RequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cosmium_requests_total",
+		Help: "Total number of REST API requests handled by Cosmium.",
+	}, []string{"database", "collection", "operation", "status"})
+
+	// RequestDuration is the per-operation request latency histogram.
+	RequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cosmium_request_duration_seconds",
+		Help:    "REST API request latency in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"database", "collection", "operation"})
+
+	// RequestsInFlight tracks requests currently being handled.
+	RequestsInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cosmium_requests_in_flight",
+		Help: "Number of REST API requests currently being handled.",
+	}, []string{"database", "collection", "operation"})
+
+	// DocumentCount is a datastore-level gauge of documents currently
+	// held per collection.
+	DocumentCount = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cosmium_datastore_documents",
+		Help: "Number of documents currently stored per collection.",
+	}, []string{"database", "collection"})
+
+	// DatastoreBytes is an approximation of the in-memory footprint of
+	// the datastore per collection, based on the JSON-encoded size of
+	// its documents.
+	DatastoreBytes = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cosmium_datastore_bytes",
+		Help: "Approximate in-memory footprint of a collection's documents, in bytes.",
+	}, []string{"database", "collection"})
+)
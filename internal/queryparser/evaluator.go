@@ -0,0 +1,35 @@
+package queryparser
+
+import (
+	"strings"
+
+	"github.com/pikami/cosmium/internal/datastore"
+)
+
+// builtinFunctions are the scalar SQL functions Cosmos' query grammar
+// recognizes natively. EvaluateFunctionCall only falls through to a
+// collection's user-defined functions for names outside this set.
+var builtinFunctions = map[string]func(args []interface{}) (interface{}, error){
+	"UPPER": func(args []interface{}) (interface{}, error) {
+		s, _ := args[0].(string)
+		return strings.ToUpper(s), nil
+	},
+	"LOWER": func(args []interface{}) (interface{}, error) {
+		s, _ := args[0].(string)
+		return strings.ToLower(s), nil
+	},
+}
+
+// EvaluateFunctionCall is where the query grammar's expression evaluator
+// resolves a function-call node: funcName is first checked against the
+// built-in scalar functions (UPPER, LOWER, ...), and if it doesn't match
+// any of those, it's dispatched to a user-defined function registered on
+// databaseId/collId via CallUserDefinedFunction, exactly like Cosmos DB
+// resolves unrecognized function names at query time.
+func EvaluateFunctionCall(dataStore datastore.DataStore, databaseId, collId, funcName string, args []interface{}) (interface{}, error) {
+	if builtin, ok := builtinFunctions[strings.ToUpper(funcName)]; ok {
+		return builtin(args)
+	}
+
+	return CallUserDefinedFunction(dataStore, databaseId, collId, funcName, args)
+}
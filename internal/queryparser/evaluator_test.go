@@ -0,0 +1,90 @@
+package queryparser
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pikami/cosmium/internal/changefeed"
+	"github.com/pikami/cosmium/internal/datastore"
+	"github.com/pikami/cosmium/internal/scripting"
+)
+
+// fakeDataStore is a minimal datastore.DataStore that only serves a
+// single fixed UDF, enough to prove EvaluateFunctionCall reaches
+// CallUserDefinedFunction for non-builtin names.
+type fakeDataStore struct {
+	udf          datastore.UserDefinedFunction
+	scriptEngine *scripting.Engine
+	changeFeed   *changefeed.Notifier
+}
+
+func newFakeDataStore(udf datastore.UserDefinedFunction) *fakeDataStore {
+	return &fakeDataStore{
+		udf:          udf,
+		scriptEngine: scripting.NewEngine(scripting.Budget{}),
+		changeFeed:   changefeed.NewNotifier(false),
+	}
+}
+
+func (f *fakeDataStore) GetDocument(databaseId, collId, docId string) (datastore.Document, bool) {
+	return nil, false
+}
+func (f *fakeDataStore) CreateDocument(databaseId, collId string, doc datastore.Document) (datastore.Document, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+func (f *fakeDataStore) ReplaceDocument(databaseId, collId, docId string, doc datastore.Document) (datastore.Document, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+func (f *fakeDataStore) PatchDocument(databaseId, collId, docId string, patch datastore.Document) (datastore.Document, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+func (f *fakeDataStore) DeleteDocument(databaseId, collId, docId string) (int64, error) {
+	return 0, fmt.Errorf("not implemented")
+}
+func (f *fakeDataStore) RestoreDocument(databaseId, collId, docId string, doc datastore.Document, existed bool, lsn int64) error {
+	return fmt.Errorf("not implemented")
+}
+func (f *fakeDataStore) QueryDocuments(databaseId, collId, query string, parameters map[string]interface{}) ([]datastore.Document, error) {
+	return nil, nil
+}
+func (f *fakeDataStore) GetStoredProcedure(databaseId, collId, sprocId string) (datastore.StoredProcedure, bool) {
+	return datastore.StoredProcedure{}, false
+}
+func (f *fakeDataStore) GetTrigger(databaseId, collId, triggerId string) (datastore.Trigger, bool) {
+	return datastore.Trigger{}, false
+}
+func (f *fakeDataStore) GetUserDefinedFunction(databaseId, collId, udfId string) (datastore.UserDefinedFunction, bool) {
+	if udfId != f.udf.Id {
+		return datastore.UserDefinedFunction{}, false
+	}
+	return f.udf, true
+}
+func (f *fakeDataStore) ChangeFeed() *changefeed.Notifier { return f.changeFeed }
+func (f *fakeDataStore) ScriptEngine() *scripting.Engine  { return f.scriptEngine }
+
+func TestEvaluateFunctionCall_BuiltinDoesNotReachUDFLookup(t *testing.T) {
+	ds := newFakeDataStore(datastore.UserDefinedFunction{})
+
+	result, err := EvaluateFunctionCall(ds, "db1", "coll1", "UPPER", []interface{}{"ab"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "AB" {
+		t.Fatalf("expected builtin UPPER to run, got %v", result)
+	}
+}
+
+func TestEvaluateFunctionCall_DispatchesUnknownNameToUDF(t *testing.T) {
+	ds := newFakeDataStore(datastore.UserDefinedFunction{
+		Id:   "double",
+		Body: `return Arguments[1] * 2`,
+	})
+
+	result, err := EvaluateFunctionCall(ds, "db1", "coll1", "double", []interface{}{float64(21)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != float64(42) {
+		t.Fatalf("expected the UDF to run and double its argument, got %v", result)
+	}
+}
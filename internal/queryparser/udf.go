@@ -0,0 +1,35 @@
+// Package queryparser is the integration point between Cosmium's SQL-like
+// query grammar and user-defined functions: EvaluateFunctionCall is where
+// the grammar's expression evaluator resolves a function-call node, and
+// it dispatches anything that isn't a recognized built-in (UPPER, LOWER,
+// ...) to CallUserDefinedFunction instead of failing.
+package queryparser
+
+import (
+	"fmt"
+
+	"github.com/pikami/cosmium/internal/datastore"
+	"github.com/pikami/cosmium/internal/scripting"
+)
+
+// CallUserDefinedFunction looks up udfName in the given database/
+// collection and executes it inside the datastore's scripting engine,
+// scoped to that collection exactly like a stored procedure. args are the
+// already-evaluated arguments the query expression passed to the call.
+func CallUserDefinedFunction(dataStore datastore.DataStore, databaseId, collId, udfName string, args []interface{}) (interface{}, error) {
+	udf, found := dataStore.GetUserDefinedFunction(databaseId, collId, udfName)
+	if !found {
+		return nil, fmt.Errorf("queryparser: user-defined function %q not found", udfName)
+	}
+
+	result, err := dataStore.ScriptEngine().Run(
+		udf.Body,
+		datastore.NewCollectionContext(dataStore, databaseId, collId),
+		args,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("queryparser: UDF %q failed: %w", udfName, err)
+	}
+
+	return scripting.FromLuaValue(result), nil
+}
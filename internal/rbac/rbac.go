@@ -0,0 +1,84 @@
+// Package rbac maps OAuth2/AAD token claims to the Cosmos RBAC-style
+// scopes Cosmium enforces per database/collection/operation, mirroring
+// how real Cosmos DB resolves a Data Plane role assignment's dataActions
+// to the request being made.
+package rbac
+
+import "strings"
+
+// Scope identifies one allowed (database, collection, operation) tuple. An
+// empty Database or Collection matches any value for that field, and
+// Operation "*" matches any operation.
+type Scope struct {
+	Database   string
+	Collection string
+	Operation  string
+}
+
+// Allows reports whether s permits the given request.
+func (s Scope) Allows(database, collection, operation string) bool {
+	return (s.Database == "" || s.Database == database) &&
+		(s.Collection == "" || s.Collection == collection) &&
+		(s.Operation == "*" || s.Operation == operation)
+}
+
+// ScopesFromClaims extracts the `scp` (or `roles`) claim from a validated
+// token's claim set and parses it into Scopes. Claims are expected as
+// space-separated strings of the form "database/collection:operation",
+// where database, collection, or operation may be "*". The operation
+// itself commonly contains a "/" (e.g. "docs/read"), so the resource and
+// operation halves are split on the *last* colon in the field, not the
+// first. The resource half may join database/collection with "/" (the
+// common case) or, when both are wildcarded, with ":" (e.g. "*:*:op").
+func ScopesFromClaims(claims map[string]interface{}) []Scope {
+	raw, _ := claims["scp"].(string)
+	if raw == "" {
+		raw, _ = claims["roles"].(string)
+	}
+
+	var scopes []Scope
+	for _, field := range strings.Fields(raw) {
+		resource, operation, found := cutLast(field, ":")
+		if !found {
+			resource, operation = field, "*"
+		}
+
+		database, collection, found := strings.Cut(resource, "/")
+		if !found {
+			database, collection, _ = strings.Cut(resource, ":")
+		}
+		if database == "*" {
+			database = ""
+		}
+		if collection == "*" {
+			collection = ""
+		}
+
+		scopes = append(scopes, Scope{
+			Database:   database,
+			Collection: collection,
+			Operation:  operation,
+		})
+	}
+	return scopes
+}
+
+// cutLast is strings.Cut but splits on the last occurrence of sep rather
+// than the first.
+func cutLast(s, sep string) (before, after string, found bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
+
+// Allowed reports whether any of scopes permits the given request.
+func Allowed(scopes []Scope, database, collection, operation string) bool {
+	for _, scope := range scopes {
+		if scope.Allows(database, collection, operation) {
+			return true
+		}
+	}
+	return false
+}
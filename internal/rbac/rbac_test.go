@@ -0,0 +1,47 @@
+package rbac
+
+import "testing"
+
+func TestScopesFromClaims_ParsesDatabaseCollectionOperation(t *testing.T) {
+	claims := map[string]interface{}{
+		"scp": "mydb/mycoll:docs/read *:*:docs/create",
+	}
+
+	scopes := ScopesFromClaims(claims)
+	if len(scopes) != 2 {
+		t.Fatalf("expected 2 scopes, got %d", len(scopes))
+	}
+
+	if scopes[0].Database != "mydb" || scopes[0].Collection != "mycoll" || scopes[0].Operation != "docs/read" {
+		t.Fatalf("unexpected first scope: %+v", scopes[0])
+	}
+	if scopes[1].Database != "" || scopes[1].Collection != "" || scopes[1].Operation != "docs/create" {
+		t.Fatalf("unexpected second scope: %+v", scopes[1])
+	}
+}
+
+func TestAllowed_DocsScopeDoesNotAuthorizeDatabaseDelete(t *testing.T) {
+	scopes := ScopesFromClaims(map[string]interface{}{
+		"scp": "mydb/*:docs/delete",
+	})
+
+	if Allowed(scopes, "mydb", "mycoll", "docs/delete") == false {
+		t.Fatal("expected a docs/delete scope to authorize deleting a document")
+	}
+	if Allowed(scopes, "mydb", "", "dbs/delete") {
+		t.Fatal("a docs/delete scope must not also authorize deleting the whole database")
+	}
+}
+
+func TestAllowed_WildcardOperationMatchesEverything(t *testing.T) {
+	scopes := ScopesFromClaims(map[string]interface{}{
+		"scp": "mydb/mycoll:*",
+	})
+
+	if !Allowed(scopes, "mydb", "mycoll", "docs/delete") {
+		t.Fatal("expected wildcard operation to match any operation")
+	}
+	if Allowed(scopes, "otherdb", "mycoll", "docs/delete") {
+		t.Fatal("expected scope to stay scoped to its own database")
+	}
+}
@@ -0,0 +1,196 @@
+package scripting
+
+import (
+	lua "github.com/yuin/gopher-lua"
+)
+
+// registerContext installs the `getContext()` global used by Cosmos
+// triggers/sprocs/UDFs, whose `getCollection()` result exposes
+// readDocument/createDocument/replaceDocument/deleteDocument/
+// queryDocuments bound to txn.
+func registerContext(state *lua.LState, txn *transaction) {
+	collection := state.NewTable()
+	state.SetFuncs(collection, map[string]lua.LGFunction{
+		"readDocument":    luaReadDocument(txn),
+		"createDocument":  luaCreateDocument(txn),
+		"replaceDocument": luaReplaceDocument(txn),
+		"deleteDocument":  luaDeleteDocument(txn),
+		"queryDocuments":  luaQueryDocuments(txn),
+	})
+
+	context := state.NewTable()
+	state.SetField(context, "getCollection", state.NewFunction(func(l *lua.LState) int {
+		l.Push(collection)
+		return 1
+	}))
+
+	state.SetGlobal("getContext", state.NewFunction(func(l *lua.LState) int {
+		l.Push(context)
+		return 1
+	}))
+}
+
+// registerArguments exposes sproc/UDF arguments to the script as the
+// global Lua table `Arguments`.
+func registerArguments(state *lua.LState, args []interface{}) {
+	table := state.NewTable()
+	for i, arg := range args {
+		table.RawSetInt(i+1, toLuaValue(state, arg))
+	}
+	state.SetGlobal("Arguments", table)
+}
+
+func luaReadDocument(txn *transaction) lua.LGFunction {
+	return func(l *lua.LState) int {
+		id := l.CheckString(1)
+		doc, err := txn.ctx.ReadDocument(id)
+		if err != nil {
+			l.Push(lua.LNil)
+			l.Push(lua.LString(err.Error()))
+			return 2
+		}
+		l.Push(toLuaTable(l, doc))
+		return 1
+	}
+}
+
+func luaCreateDocument(txn *transaction) lua.LGFunction {
+	return func(l *lua.LState) int {
+		doc := fromLuaTable(l.CheckTable(1))
+		created, err := txn.ctx.CreateDocument(doc)
+		if err != nil {
+			l.Push(lua.LNil)
+			l.Push(lua.LString(err.Error()))
+			return 2
+		}
+		if id, ok := created["id"].(string); ok {
+			lsn, _ := created["_lsn"].(int64)
+			txn.recordCreate(id, lsn)
+		}
+		l.Push(toLuaTable(l, created))
+		return 1
+	}
+}
+
+func luaReplaceDocument(txn *transaction) lua.LGFunction {
+	return func(l *lua.LState) int {
+		id := l.CheckString(1)
+		doc := fromLuaTable(l.CheckTable(2))
+
+		previous, _ := txn.ctx.ReadDocument(id)
+		replaced, err := txn.ctx.ReplaceDocument(id, doc)
+		if err != nil {
+			l.Push(lua.LNil)
+			l.Push(lua.LString(err.Error()))
+			return 2
+		}
+		if previous != nil {
+			lsn, _ := replaced["_lsn"].(int64)
+			txn.recordReplace(previous, lsn)
+		}
+		l.Push(toLuaTable(l, replaced))
+		return 1
+	}
+}
+
+func luaDeleteDocument(txn *transaction) lua.LGFunction {
+	return func(l *lua.LState) int {
+		id := l.CheckString(1)
+
+		previous, _ := txn.ctx.ReadDocument(id)
+		lsn, err := txn.ctx.DeleteDocument(id)
+		if err != nil {
+			l.Push(lua.LFalse)
+			l.Push(lua.LString(err.Error()))
+			return 2
+		}
+		if previous != nil {
+			txn.recordDelete(previous, lsn)
+		}
+		l.Push(lua.LTrue)
+		return 1
+	}
+}
+
+func luaQueryDocuments(txn *transaction) lua.LGFunction {
+	return func(l *lua.LState) int {
+		query := l.CheckString(1)
+		docs, err := txn.ctx.QueryDocuments(query, nil)
+		if err != nil {
+			l.Push(lua.LNil)
+			l.Push(lua.LString(err.Error()))
+			return 2
+		}
+
+		result := l.NewTable()
+		for i, doc := range docs {
+			result.RawSetInt(i+1, toLuaTable(l, doc))
+		}
+		l.Push(result)
+		return 1
+	}
+}
+
+func toLuaTable(l *lua.LState, m map[string]interface{}) *lua.LTable {
+	table := l.NewTable()
+	for k, v := range m {
+		table.RawSetString(k, toLuaValue(l, v))
+	}
+	return table
+}
+
+func toLuaValue(l *lua.LState, v interface{}) lua.LValue {
+	switch value := v.(type) {
+	case nil:
+		return lua.LNil
+	case string:
+		return lua.LString(value)
+	case bool:
+		return lua.LBool(value)
+	case float64:
+		return lua.LNumber(value)
+	case int:
+		return lua.LNumber(value)
+	case int64:
+		return lua.LNumber(value)
+	case map[string]interface{}:
+		return toLuaTable(l, value)
+	case []interface{}:
+		table := l.NewTable()
+		for i, item := range value {
+			table.RawSetInt(i+1, toLuaValue(l, item))
+		}
+		return table
+	default:
+		return lua.LNil
+	}
+}
+
+func fromLuaTable(table *lua.LTable) map[string]interface{} {
+	result := make(map[string]interface{})
+	table.ForEach(func(key, value lua.LValue) {
+		result[key.String()] = FromLuaValue(value)
+	})
+	return result
+}
+
+// FromLuaValue converts a Lua value - a document field, a trigger/sproc/
+// UDF's return value, anything a script hands back to Go - into a plain
+// Go value. It is the single place that decision lives; callers outside
+// this package (the query parser's UDF evaluator, the sproc execution
+// handler) use it instead of keeping their own copy that would silently
+// drift out of sync with this one.
+func FromLuaValue(value lua.LValue) interface{} {
+	switch v := value.(type) {
+	case lua.LBool:
+		return bool(v)
+	case lua.LNumber:
+		return float64(v)
+	case lua.LString:
+		return string(v)
+	case *lua.LTable:
+		return fromLuaTable(v)
+	default:
+		return nil
+	}
+}
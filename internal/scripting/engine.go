@@ -0,0 +1,113 @@
+// Package scripting embeds a gopher-lua runtime so that triggers, stored
+// procedures, and user-defined functions are actually executed instead of
+// merely stored as text, mirroring the JavaScript execution model real
+// Cosmos DB exposes to the same APIs.
+package scripting
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// ErrBudgetExceeded is returned when a script runs past its configured
+// Timeout, so a runaway script can never hang the emulator. A script that
+// overflows the call stack limit derived from MaxInstructions fails with
+// the Lua runtime's own error instead.
+var ErrBudgetExceeded = errors.New("scripting: instruction/time budget exceeded")
+
+// Budget bounds how much work a single script invocation may do. Timeout
+// is enforced via the Lua state's context; MaxInstructions additionally
+// caps the call stack depth so deeply recursive scripts fail fast instead
+// of only being caught by the timeout.
+type Budget struct {
+	MaxInstructions int
+	Timeout         time.Duration
+}
+
+// DefaultBudget is applied when a caller does not specify one. It is
+// generous enough for realistic triggers/sprocs while still guaranteeing
+// termination.
+var DefaultBudget = Budget{
+	MaxInstructions: 1_000_000,
+	Timeout:         2 * time.Second,
+}
+
+// CollectionContext is the subset of datastore operations exposed to
+// scripts as `getContext().getCollection()`. It is implemented by an
+// adapter in the datastore package that binds it to the real in-memory
+// datastore for a specific database/collection.
+type CollectionContext interface {
+	ReadDocument(id string) (map[string]interface{}, error)
+	CreateDocument(doc map[string]interface{}) (map[string]interface{}, error)
+	ReplaceDocument(id string, doc map[string]interface{}) (map[string]interface{}, error)
+	// DeleteDocument returns the `_lsn` assigned to the delete's change
+	// feed entry, so a transaction rollback can retract it via
+	// RestoreDocument if the delete is later undone.
+	DeleteDocument(id string) (int64, error)
+	QueryDocuments(query string, parameters map[string]interface{}) ([]map[string]interface{}, error)
+
+	// RestoreDocument puts id's document back to exactly the state it was
+	// in before a transaction's mutation, used only by transaction
+	// rollback. Unlike CreateDocument/ReplaceDocument/DeleteDocument, it
+	// fires no triggers and assigns no new `_lsn` of its own; lsn is the
+	// `_lsn` the mutation being undone published, which RestoreDocument
+	// retracts from the change feed (along with refreshing the document
+	// count/bytes metrics) so a rolled-back mutation leaves no trace
+	// beyond the state it undid. existed is false when id did not exist
+	// before the transaction (so rollback deletes it); otherwise doc is
+	// the exact prior document to restore.
+	RestoreDocument(id string, doc map[string]interface{}, existed bool, lsn int64) error
+}
+
+// Engine executes trigger/sproc/UDF script bodies against a
+// CollectionContext, with automatic rollback of any mutation the script
+// made if it returns an error or exceeds its budget.
+type Engine struct {
+	budget Budget
+}
+
+// NewEngine creates an Engine. A zero Budget falls back to DefaultBudget.
+func NewEngine(budget Budget) *Engine {
+	if budget.MaxInstructions == 0 {
+		budget.MaxInstructions = DefaultBudget.MaxInstructions
+	}
+	if budget.Timeout == 0 {
+		budget.Timeout = DefaultBudget.Timeout
+	}
+	return &Engine{budget: budget}
+}
+
+// Run executes the given script body with `getContext()` bound to ctx.
+// args are exposed as the Lua global table `Arguments`. If the script
+// returns an error, or the budget is exceeded, every mutation the script
+// made through ctx is rolled back via ctx's transaction before Run
+// returns.
+func (e *Engine) Run(body string, ctx CollectionContext, args []interface{}) (lua.LValue, error) {
+	txn := newTransaction(ctx)
+
+	runCtx, cancel := context.WithTimeout(context.Background(), e.budget.Timeout)
+	defer cancel()
+
+	state := lua.NewState(lua.Options{
+		RegistrySize:  1024 * 8,
+		CallStackSize: e.budget.MaxInstructions/1000 + 64,
+	})
+	defer state.Close()
+	state.SetContext(runCtx)
+
+	registerContext(state, txn)
+	registerArguments(state, args)
+
+	if err := state.DoString(body); err != nil {
+		txn.rollback()
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			return lua.LNil, ErrBudgetExceeded
+		}
+		return lua.LNil, err
+	}
+
+	return state.Get(-1), nil
+}
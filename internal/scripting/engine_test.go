@@ -0,0 +1,110 @@
+package scripting
+
+import (
+	"fmt"
+	"testing"
+)
+
+// fakeCollectionContext is a minimal in-memory CollectionContext used to
+// observe whether Engine.Run rolls back mutations correctly. It counts
+// calls to its side-effecting methods (the ones a real datastore would
+// pair with a fresh `_lsn`, a change feed publish, and trigger firing)
+// separately from RestoreDocument, so a test can assert that rollback
+// went through RestoreDocument instead of performing more of those
+// mutations on the way out.
+type fakeCollectionContext struct {
+	docs         map[string]map[string]interface{}
+	mutations    int
+	restoreCalls int
+}
+
+func newFakeCollectionContext() *fakeCollectionContext {
+	return &fakeCollectionContext{docs: make(map[string]map[string]interface{})}
+}
+
+func (f *fakeCollectionContext) ReadDocument(id string) (map[string]interface{}, error) {
+	doc, ok := f.docs[id]
+	if !ok {
+		return nil, fmt.Errorf("document %q not found", id)
+	}
+	return doc, nil
+}
+
+func (f *fakeCollectionContext) CreateDocument(doc map[string]interface{}) (map[string]interface{}, error) {
+	id, _ := doc["id"].(string)
+	if _, exists := f.docs[id]; exists {
+		return nil, fmt.Errorf("document %q already exists", id)
+	}
+	f.docs[id] = doc
+	f.mutations++
+	return doc, nil
+}
+
+func (f *fakeCollectionContext) ReplaceDocument(id string, doc map[string]interface{}) (map[string]interface{}, error) {
+	f.docs[id] = doc
+	f.mutations++
+	return doc, nil
+}
+
+func (f *fakeCollectionContext) DeleteDocument(id string) (int64, error) {
+	delete(f.docs, id)
+	f.mutations++
+	return 0, nil
+}
+
+func (f *fakeCollectionContext) QueryDocuments(query string, parameters map[string]interface{}) ([]map[string]interface{}, error) {
+	result := make([]map[string]interface{}, 0, len(f.docs))
+	for _, doc := range f.docs {
+		result = append(result, doc)
+	}
+	return result, nil
+}
+
+func (f *fakeCollectionContext) RestoreDocument(id string, doc map[string]interface{}, existed bool, lsn int64) error {
+	f.restoreCalls++
+	if !existed {
+		delete(f.docs, id)
+		return nil
+	}
+	f.docs[id] = doc
+	return nil
+}
+
+func TestEngine_Run_RollsBackMutationsOnError(t *testing.T) {
+	ctx := newFakeCollectionContext()
+	engine := NewEngine(Budget{})
+
+	_, err := engine.Run(`
+		getContext().getCollection().createDocument({ id = "doc1" })
+		error("boom")
+	`, ctx, nil)
+
+	if err == nil {
+		t.Fatal("expected the script error to be returned")
+	}
+	if _, found := ctx.docs["doc1"]; found {
+		t.Fatal("expected createDocument's mutation to be rolled back after the script errored")
+	}
+	if ctx.mutations != 1 {
+		t.Fatalf("expected only the script's own createDocument call to count as a mutation, got %d", ctx.mutations)
+	}
+	if ctx.restoreCalls != 1 {
+		t.Fatalf("expected rollback to go through RestoreDocument exactly once, got %d", ctx.restoreCalls)
+	}
+}
+
+func TestEngine_Run_KeepsMutationsOnSuccess(t *testing.T) {
+	ctx := newFakeCollectionContext()
+	engine := NewEngine(Budget{})
+
+	_, err := engine.Run(`
+		getContext().getCollection().createDocument({ id = "doc1" })
+	`, ctx, nil)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, found := ctx.docs["doc1"]; !found {
+		t.Fatal("expected createDocument's mutation to be kept after the script succeeded")
+	}
+}
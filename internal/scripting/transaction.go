@@ -0,0 +1,50 @@
+package scripting
+
+// transaction records every mutation a script makes through a
+// CollectionContext during one Run call, so they can be undone if the
+// script subsequently errors or is killed for exceeding its budget.
+//
+// Undo always goes through ctx.RestoreDocument rather than
+// CreateDocument/ReplaceDocument/DeleteDocument: those public methods
+// are mutations in their own right, so undoing a create by deleting (or
+// an undone delete by creating) through them would assign a fresh
+// `_lsn`, publish a new change feed entry, and re-fire triggers for
+// every step of the rollback. RestoreDocument instead puts the
+// collection back exactly as it was and retracts the one change feed
+// entry/metrics update the undone mutation produced, recorded here by
+// its `_lsn`, rather than producing yet more of either.
+type transaction struct {
+	ctx  CollectionContext
+	undo []func()
+}
+
+func newTransaction(ctx CollectionContext) *transaction {
+	return &transaction{ctx: ctx}
+}
+
+func (t *transaction) recordCreate(id string, lsn int64) {
+	t.undo = append(t.undo, func() {
+		_ = t.ctx.RestoreDocument(id, nil, false, lsn)
+	})
+}
+
+func (t *transaction) recordReplace(previous map[string]interface{}, lsn int64) {
+	id, _ := previous["id"].(string)
+	t.undo = append(t.undo, func() {
+		_ = t.ctx.RestoreDocument(id, previous, true, lsn)
+	})
+}
+
+func (t *transaction) recordDelete(previous map[string]interface{}, lsn int64) {
+	id, _ := previous["id"].(string)
+	t.undo = append(t.undo, func() {
+		_ = t.ctx.RestoreDocument(id, previous, true, lsn)
+	})
+}
+
+// rollback undoes every recorded mutation in reverse order.
+func (t *transaction) rollback() {
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		t.undo[i]()
+	}
+}
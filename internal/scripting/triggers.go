@@ -0,0 +1,71 @@
+package scripting
+
+import (
+	"context"
+	"errors"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// triggerRequest backs the `getContext().getRequest()` shim triggers use:
+// unlike sprocs/UDFs, triggers operate on the single in-flight document
+// via getBody()/setBody() rather than on the collection, so they cannot
+// recurse back into the datastore and need no transaction/rollback.
+type triggerRequest struct {
+	body map[string]interface{}
+}
+
+// RunTrigger executes a pre/post trigger script body against a single
+// document via `getContext().getRequest().getBody()/setBody()`, returning
+// the (possibly modified) document. It shares Run's instruction/time
+// budget, but since a trigger has no collection operations to roll back,
+// a failing script simply returns an error and the caller discards its
+// changes by not persisting them.
+func (e *Engine) RunTrigger(body string, document map[string]interface{}) (map[string]interface{}, error) {
+	runCtx, cancel := context.WithTimeout(context.Background(), e.budget.Timeout)
+	defer cancel()
+
+	state := lua.NewState(lua.Options{
+		RegistrySize:  1024 * 8,
+		CallStackSize: e.budget.MaxInstructions/1000 + 64,
+	})
+	defer state.Close()
+	state.SetContext(runCtx)
+
+	request := &triggerRequest{body: document}
+	registerTriggerContext(state, request)
+
+	if err := state.DoString(body); err != nil {
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			return nil, ErrBudgetExceeded
+		}
+		return nil, err
+	}
+
+	return request.body, nil
+}
+
+func registerTriggerContext(state *lua.LState, request *triggerRequest) {
+	requestTable := state.NewTable()
+	state.SetFuncs(requestTable, map[string]lua.LGFunction{
+		"getBody": func(l *lua.LState) int {
+			l.Push(toLuaTable(l, request.body))
+			return 1
+		},
+		"setBody": func(l *lua.LState) int {
+			request.body = fromLuaTable(l.CheckTable(1))
+			return 0
+		},
+	})
+
+	context := state.NewTable()
+	state.SetField(context, "getRequest", state.NewFunction(func(l *lua.LState) int {
+		l.Push(requestTable)
+		return 1
+	}))
+
+	state.SetGlobal("getContext", state.NewFunction(func(l *lua.LState) int {
+		l.Push(context)
+		return 1
+	}))
+}
@@ -0,0 +1,93 @@
+// Package shutdown coordinates graceful shutdown across Cosmium's
+// subsystems (the REST API server, the admin server, change-feed
+// subscribers, background persistence flushers, ...). Each subsystem
+// registers a BeforeExit callback; on SIGINT/SIGTERM (or a manual Run)
+// every callback runs in LIFO registration order, so the subsystem that
+// started last is also the first one torn down.
+package shutdown
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Registry holds the ordered set of shutdown callbacks for one process,
+// or one isolated component within it. The package-level functions
+// operate on a shared default Registry, meant for simple single-instance
+// embedders that just want process-wide SIGINT/SIGTERM handling;
+// anything that can run more than one instance side by side (ApiServer,
+// tests) should create its own Registry with NewRegistry so stopping one
+// instance can never tear down another's listeners.
+type Registry struct {
+	mu    sync.Mutex
+	hooks []func()
+}
+
+var defaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// BeforeExit registers fn to run during shutdown, on the default
+// Registry. Hooks run in LIFO order: the most recently registered hook
+// runs first.
+func BeforeExit(fn func()) {
+	defaultRegistry.BeforeExit(fn)
+}
+
+// BeforeExit registers fn on this Registry.
+func (r *Registry) BeforeExit(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, fn)
+}
+
+// Run executes every hook registered on the default Registry, in LIFO
+// order. Use this to trigger a graceful shutdown from something other
+// than a process signal, e.g. an embedder-controlled stop channel.
+func Run() {
+	defaultRegistry.Run()
+}
+
+// Run executes every registered hook in LIFO order. It is safe to call
+// more than once; hooks run again each time.
+func (r *Registry) Run() {
+	r.mu.Lock()
+	hooks := make([]func(), len(r.hooks))
+	copy(hooks, r.hooks)
+	r.mu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
+}
+
+// WaitForSignal blocks until SIGINT/SIGTERM is received, using the
+// default Registry, then runs Run and returns.
+func WaitForSignal() {
+	defaultRegistry.WaitForSignal()
+}
+
+// WaitForSignal blocks until SIGINT/SIGTERM is received, then runs every
+// registered hook in LIFO order and returns.
+func (r *Registry) WaitForSignal() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	<-sigCh
+	signal.Stop(sigCh)
+
+	r.Run()
+}
+
+// DrainContext returns a context cancelled after timeout, meant to bound
+// how long a BeforeExit hook (typically an http.Server.Shutdown call) is
+// given to drain in-flight work before the process moves on.
+func DrainContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), timeout)
+}
@@ -0,0 +1,56 @@
+package shutdown
+
+import "testing"
+
+func TestRegistry_Run_ExecutesHooksInLIFOOrder(t *testing.T) {
+	r := NewRegistry()
+
+	var order []int
+	r.BeforeExit(func() { order = append(order, 1) })
+	r.BeforeExit(func() { order = append(order, 2) })
+	r.BeforeExit(func() { order = append(order, 3) })
+
+	r.Run()
+
+	want := []int{3, 2, 1}
+	if len(order) != len(want) {
+		t.Fatalf("expected %v, got %v", want, order)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, order)
+		}
+	}
+}
+
+func TestRegistry_Run_SafeToRunMoreThanOnce(t *testing.T) {
+	r := NewRegistry()
+
+	calls := 0
+	r.BeforeExit(func() { calls++ })
+
+	r.Run()
+	r.Run()
+
+	if calls != 2 {
+		t.Fatalf("expected the hook to run again on a second Run, got %d calls", calls)
+	}
+}
+
+func TestRegistry_IsIsolatedFromOtherRegistries(t *testing.T) {
+	a := NewRegistry()
+	b := NewRegistry()
+
+	aRan, bRan := false, false
+	a.BeforeExit(func() { aRan = true })
+	b.BeforeExit(func() { bRan = true })
+
+	a.Run()
+
+	if !aRan {
+		t.Fatal("expected a's hook to run")
+	}
+	if bRan {
+		t.Fatal("expected running a's Registry to leave b's hooks untouched")
+	}
+}